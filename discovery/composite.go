@@ -0,0 +1,71 @@
+package discovery
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/The-Data-Appeal-Company/presto-exporter/presto"
+)
+
+// CompositeProvider merges the results of several providers into a single
+// set of clusters, de-duplicated by name: the first provider to report a
+// given name wins.
+type CompositeProvider struct {
+	sources []Provider
+}
+
+// NewCompositeProvider builds a CompositeProvider over the given providers.
+// Wrap a provider with WithPrefix first to avoid name collisions between
+// sources.
+func NewCompositeProvider(sources ...Provider) *CompositeProvider {
+	return &CompositeProvider{sources: sources}
+}
+
+func (c *CompositeProvider) Provide() (map[string]presto.ClusterInfo, error) {
+	merged := make(map[string]presto.ClusterInfo)
+
+	for _, source := range c.sources {
+		clusters, err := source.Provide()
+		if err != nil {
+			return nil, fmt.Errorf("discovery provider failed: %w", err)
+		}
+
+		for name, cluster := range clusters {
+			if _, exists := merged[name]; exists {
+				logrus.Warnf("duplicate cluster name %q returned by multiple discovery providers, keeping the first one seen", name)
+				continue
+			}
+			merged[name] = cluster
+		}
+	}
+
+	return merged, nil
+}
+
+// prefixedProvider tags every cluster name returned by a provider with a
+// fixed prefix, e.g. turning "analytics" into "emr-analytics".
+type prefixedProvider struct {
+	provider Provider
+	prefix   string
+}
+
+// WithPrefix wraps a provider so CompositeProvider can tell its clusters
+// apart from those of other sources.
+func WithPrefix(provider Provider, prefix string) Provider {
+	return &prefixedProvider{provider: provider, prefix: prefix}
+}
+
+func (p *prefixedProvider) Provide() (map[string]presto.ClusterInfo, error) {
+	clusters, err := p.provider.Provide()
+	if err != nil {
+		return nil, err
+	}
+
+	prefixed := make(map[string]presto.ClusterInfo, len(clusters))
+	for name, cluster := range clusters {
+		prefixed[fmt.Sprintf("%s-%s", p.prefix, name)] = cluster
+	}
+
+	return prefixed, nil
+}
@@ -0,0 +1,72 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/The-Data-Appeal-Company/presto-exporter/presto"
+)
+
+// staticProvider is a fixed-result Provider stub for testing composition.
+type staticProvider struct {
+	clusters map[string]presto.ClusterInfo
+	err      error
+}
+
+func (p *staticProvider) Provide() (map[string]presto.ClusterInfo, error) {
+	return p.clusters, p.err
+}
+
+func TestCompositeProviderDedup(t *testing.T) {
+	first := &staticProvider{clusters: map[string]presto.ClusterInfo{
+		"analytics": {Host: "http://first"},
+	}}
+	second := &staticProvider{clusters: map[string]presto.ClusterInfo{
+		"analytics": {Host: "http://second"},
+		"reporting": {Host: "http://second-reporting"},
+	}}
+
+	composite := NewCompositeProvider(first, second)
+
+	clusters, err := composite.Provide()
+	if err != nil {
+		t.Fatalf("Provide() returned error: %v", err)
+	}
+
+	if got, want := len(clusters), 2; got != want {
+		t.Fatalf("len(clusters) = %d, want %d", got, want)
+	}
+
+	if got := clusters["analytics"].Host; got != "http://first" {
+		t.Fatalf("analytics host = %q, want the first provider to win", got)
+	}
+	if got := clusters["reporting"].Host; got != "http://second-reporting" {
+		t.Fatalf("reporting host = %q, want %q", got, "http://second-reporting")
+	}
+}
+
+func TestWithPrefixAvoidsCollisions(t *testing.T) {
+	first := &staticProvider{clusters: map[string]presto.ClusterInfo{
+		"analytics": {Host: "http://first"},
+	}}
+	second := &staticProvider{clusters: map[string]presto.ClusterInfo{
+		"analytics": {Host: "http://second"},
+	}}
+
+	composite := NewCompositeProvider(WithPrefix(first, "file"), WithPrefix(second, "k8s"))
+
+	clusters, err := composite.Provide()
+	if err != nil {
+		t.Fatalf("Provide() returned error: %v", err)
+	}
+
+	if got, want := len(clusters), 2; got != want {
+		t.Fatalf("len(clusters) = %d, want %d (prefixing should avoid the dedup collision)", got, want)
+	}
+
+	if got := clusters["file-analytics"].Host; got != "http://first" {
+		t.Fatalf("file-analytics host = %q, want %q", got, "http://first")
+	}
+	if got := clusters["k8s-analytics"].Host; got != "http://second" {
+		t.Fatalf("k8s-analytics host = %q, want %q", got, "http://second")
+	}
+}
@@ -0,0 +1,49 @@
+package discovery
+
+import (
+	"fmt"
+
+	consulapi "github.com/hashicorp/consul/api"
+
+	"github.com/The-Data-Appeal-Company/presto-exporter/presto"
+)
+
+// ConsulProvider discovers clusters from healthy Consul service instances,
+// one cluster per service instance.
+type ConsulProvider struct {
+	client      *consulapi.Client
+	serviceName string
+	tag         string
+}
+
+// NewConsulProvider builds a provider querying address (empty uses the
+// consul/api default of CONSUL_HTTP_ADDR / http://127.0.0.1:8500) for
+// healthy instances of serviceName, optionally filtered by tag.
+func NewConsulProvider(address, serviceName, tag string) (*ConsulProvider, error) {
+	config := consulapi.DefaultConfig()
+	if address != "" {
+		config.Address = address
+	}
+
+	client, err := consulapi.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("building consul client: %w", err)
+	}
+
+	return &ConsulProvider{client: client, serviceName: serviceName, tag: tag}, nil
+}
+
+func (p *ConsulProvider) Provide() (map[string]presto.ClusterInfo, error) {
+	entries, _, err := p.client.Health().Service(p.serviceName, p.tag, true, nil)
+	if err != nil {
+		return nil, fmt.Errorf("querying consul for service %s: %w", p.serviceName, err)
+	}
+
+	clusters := make(map[string]presto.ClusterInfo, len(entries))
+	for _, entry := range entries {
+		host := fmt.Sprintf("http://%s:%d", entry.Service.Address, entry.Service.Port)
+		clusters[entry.Service.ID] = presto.ClusterInfo{Host: host}
+	}
+
+	return clusters, nil
+}
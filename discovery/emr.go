@@ -1,71 +1,75 @@
-package aws
+package discovery
 
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
+
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/emr"
 	"github.com/patrickmn/go-cache"
-	"strings"
-	"time"
-	"trino-exporter/trino"
+
+	"github.com/The-Data-Appeal-Company/presto-exporter/presto"
 )
 
-type ClusterProvider struct {
+// EMRProvider discovers clusters by listing AWS EMR clusters with Trino/
+// Presto installed and resolving each one's master instance.
+type EMRProvider struct {
 	emrClient *emr.EMR
 	ec2Client *ec2.EC2
 	cache     *cache.Cache
 }
 
-func NewClusterProvider() *ClusterProvider {
+func NewEMRProvider() *EMRProvider {
 	sess := session.Must(session.NewSessionWithOptions(session.Options{
 		SharedConfigState: session.SharedConfigEnable,
 	}))
 
-	return &ClusterProvider{
+	return &EMRProvider{
 		emrClient: emr.New(sess),
 		ec2Client: ec2.New(sess),
 		cache:     cache.New(60*time.Minute, 24*time.Hour),
 	}
 }
 
-const cacheKey = "master"
+const emrCacheKey = "master"
 
-func (c *ClusterProvider) Provide() (map[string]trino.ClusterInfo, error) {
-	result, cached := c.cache.Get(cacheKey)
+func (p *EMRProvider) Provide() (map[string]presto.ClusterInfo, error) {
+	result, cached := p.cache.Get(emrCacheKey)
 	if cached {
-		return result.(map[string]trino.ClusterInfo), nil
+		return result.(map[string]presto.ClusterInfo), nil
 	}
 
-	masters, err := c.listTargetMasters(context.Background())
+	masters, err := p.listTargetMasters(context.Background())
 	if err != nil {
 		return nil, err
 	}
 
-	c.cache.Set(cacheKey, masters, 30*time.Minute)
+	p.cache.Set(emrCacheKey, masters, 30*time.Minute)
 
 	return masters, nil
 }
 
-func (c *ClusterProvider) listTargetMasters(ctx context.Context) (map[string]trino.ClusterInfo, error) {
+func (p *EMRProvider) listTargetMasters(ctx context.Context) (map[string]presto.ClusterInfo, error) {
 
-	clusterWithMaster := make(map[string]trino.ClusterInfo)
+	clusterWithMaster := make(map[string]presto.ClusterInfo)
 
-	clusters, err := c.listTargetClusters(ctx)
+	clusters, err := p.listTargetClusters(ctx)
 
 	if err != nil {
 		return nil, err
 	}
 
 	for _, cluster := range clusters {
-		master, err := c.getClusterMasterInstance(cluster)
+		master, err := p.getClusterMasterInstance(cluster)
 		if err != nil {
 			return nil, err
 		}
 
-		clusterWithMaster[*cluster.Cluster.Name] = trino.ClusterInfo{
+		clusterWithMaster[*cluster.Cluster.Name] = presto.ClusterInfo{
 			Host: fmt.Sprintf("http://%s:8889", master),
 		}
 	}
@@ -73,17 +77,17 @@ func (c *ClusterProvider) listTargetMasters(ctx context.Context) (map[string]tri
 	return clusterWithMaster, nil
 }
 
-func (c *ClusterProvider) listTargetClusters(ctx context.Context) ([]*emr.DescribeClusterOutput, error) {
+func (p *EMRProvider) listTargetClusters(ctx context.Context) ([]*emr.DescribeClusterOutput, error) {
 	req := &emr.ListClustersInput{
 		ClusterStates: aws.StringSlice([]string{"WAITING"}),
 	}
 
 	clusters := make([]*emr.DescribeClusterOutput, 0)
-	err := c.emrClient.ListClustersPagesWithContext(ctx, req, func(output *emr.ListClustersOutput, b bool) bool {
+	err := p.emrClient.ListClustersPagesWithContext(ctx, req, func(output *emr.ListClustersOutput, b bool) bool {
 
 		for _, cluster := range output.Clusters {
 
-			descr, _ := c.emrClient.DescribeCluster(&emr.DescribeClusterInput{
+			descr, _ := p.emrClient.DescribeCluster(&emr.DescribeClusterInput{
 				ClusterId: cluster.Id,
 			})
 
@@ -100,22 +104,22 @@ func (c *ClusterProvider) listTargetClusters(ctx context.Context) ([]*emr.Descri
 	return clusters, err
 }
 
-func (c *ClusterProvider) getClusterMasterInstance(cluster *emr.DescribeClusterOutput) (string, error) {
+func (p *EMRProvider) getClusterMasterInstance(cluster *emr.DescribeClusterOutput) (string, error) {
 
 	instanceCollectionType := cluster.Cluster.InstanceCollectionType
 
 	if *instanceCollectionType == emr.InstanceCollectionTypeInstanceGroup {
-		return c.getMasterInstanceForNodeGroup(cluster)
+		return p.getMasterInstanceForNodeGroup(cluster)
 	} else if *instanceCollectionType == emr.InstanceCollectionTypeInstanceFleet {
-		return c.getMasterInstanceForFleet(cluster)
+		return p.getMasterInstanceForFleet(cluster)
 	}
 
 	return "", fmt.Errorf("unrecognized instance type %s", *instanceCollectionType)
 }
 
-func (c *ClusterProvider) getMasterInstanceForFleet(cluster *emr.DescribeClusterOutput) (string, error) {
+func (p *EMRProvider) getMasterInstanceForFleet(cluster *emr.DescribeClusterOutput) (string, error) {
 
-	instances, err := c.emrClient.ListInstances(&emr.ListInstancesInput{
+	instances, err := p.emrClient.ListInstances(&emr.ListInstancesInput{
 		ClusterId:         cluster.Cluster.Id,
 		InstanceFleetType: aws.String(emr.InstanceFleetTypeMaster),
 	})
@@ -131,9 +135,9 @@ func (c *ClusterProvider) getMasterInstanceForFleet(cluster *emr.DescribeCluster
 	return *instances.Instances[0].PrivateIpAddress, nil
 }
 
-func (c *ClusterProvider) getMasterInstanceForNodeGroup(cluster *emr.DescribeClusterOutput) (string, error) {
+func (p *EMRProvider) getMasterInstanceForNodeGroup(cluster *emr.DescribeClusterOutput) (string, error) {
 
-	instanceGroups, err := c.emrClient.ListInstances(&emr.ListInstancesInput{
+	instanceGroups, err := p.emrClient.ListInstances(&emr.ListInstancesInput{
 		ClusterId:          cluster.Cluster.Id,
 		InstanceGroupTypes: []*string{aws.String(emr.InstanceGroupTypeMaster)},
 	})
@@ -144,7 +148,7 @@ func (c *ClusterProvider) getMasterInstanceForNodeGroup(cluster *emr.DescribeClu
 
 	for _, group := range instanceGroups.Instances {
 
-		instances, err := c.emrClient.ListInstances(&emr.ListInstancesInput{
+		instances, err := p.emrClient.ListInstances(&emr.ListInstancesInput{
 			ClusterId:       cluster.Cluster.Id,
 			InstanceGroupId: group.Id,
 		})
@@ -0,0 +1,99 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+
+	"github.com/The-Data-Appeal-Company/presto-exporter/presto"
+)
+
+// FileProvider reads a static list of clusters from a YAML or JSON file,
+// keyed by cluster name, and reloads it whenever the file changes on disk.
+type FileProvider struct {
+	path string
+
+	mu       sync.RWMutex
+	clusters map[string]presto.ClusterInfo
+}
+
+// NewFileProvider loads path and starts watching it for changes. The file
+// format (YAML or JSON) is inferred from its extension.
+func NewFileProvider(path string) (*FileProvider, error) {
+	p := &FileProvider{path: path}
+
+	if err := p.reload(); err != nil {
+		return nil, err
+	}
+
+	if err := p.watch(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p *FileProvider) Provide() (map[string]presto.ClusterInfo, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.clusters, nil
+}
+
+func (p *FileProvider) reload() error {
+	raw, err := ioutil.ReadFile(p.path)
+	if err != nil {
+		return fmt.Errorf("reading cluster file %s: %w", p.path, err)
+	}
+
+	clusters := make(map[string]presto.ClusterInfo)
+
+	if strings.EqualFold(filepath.Ext(p.path), ".json") {
+		err = json.Unmarshal(raw, &clusters)
+	} else {
+		err = yaml.Unmarshal(raw, &clusters)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing cluster file %s: %w", p.path, err)
+	}
+
+	p.mu.Lock()
+	p.clusters = clusters
+	p.mu.Unlock()
+
+	return nil
+}
+
+func (p *FileProvider) watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating file watcher: %w", err)
+	}
+
+	if err := watcher.Add(filepath.Dir(p.path)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("watching %s: %w", p.path, err)
+	}
+
+	go func() {
+		for event := range watcher.Events {
+			if filepath.Clean(event.Name) != filepath.Clean(p.path) {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			if err := p.reload(); err != nil {
+				logrus.Errorf("reloading cluster file %s: %s", p.path, err)
+			}
+		}
+	}()
+
+	return nil
+}
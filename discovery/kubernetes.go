@@ -0,0 +1,107 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/The-Data-Appeal-Company/presto-exporter/presto"
+)
+
+// KubernetesProvider discovers Trino coordinators from Kubernetes Endpoints
+// matching a label selector on their Service, watched via an informer so
+// the exporter sees additions/removals without polling. Endpoints are
+// watched rather than Services so that a Service with no ready backing pods
+// isn't reported as a scrapeable cluster.
+type KubernetesProvider struct {
+	namespace     string
+	labelSelector string
+	port          int
+
+	store cache.Store
+}
+
+// NewKubernetesProvider builds a provider using the in-cluster service
+// account, watching Endpoints in namespace whose Service matches
+// labelSelector. port is the coordinator's HTTP port, since Services don't
+// always name it.
+func NewKubernetesProvider(namespace, labelSelector string, port int) (*KubernetesProvider, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading in-cluster config: %w", err)
+	}
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("building kubernetes client: %w", err)
+	}
+
+	p := &KubernetesProvider{
+		namespace:     namespace,
+		labelSelector: labelSelector,
+		port:          port,
+	}
+
+	p.watch(client)
+
+	return p, nil
+}
+
+func (p *KubernetesProvider) watch(client kubernetes.Interface) {
+	listWatch := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector = p.labelSelector
+			return client.CoreV1().Endpoints(p.namespace).List(context.TODO(), options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector = p.labelSelector
+			return client.CoreV1().Endpoints(p.namespace).Watch(context.TODO(), options)
+		},
+	}
+
+	store, controller := cache.NewInformer(listWatch, &corev1.Endpoints{}, 0, cache.ResourceEventHandlerFuncs{})
+	p.store = store
+
+	go controller.Run(wait.NeverStop)
+}
+
+func (p *KubernetesProvider) Provide() (map[string]presto.ClusterInfo, error) {
+	clusters := make(map[string]presto.ClusterInfo)
+
+	for _, obj := range p.store.List() {
+		endpoints, ok := obj.(*corev1.Endpoints)
+		if !ok {
+			continue
+		}
+
+		if !hasReadyAddress(endpoints) {
+			continue
+		}
+
+		host := fmt.Sprintf("http://%s.%s.svc.cluster.local:%d", endpoints.Name, endpoints.Namespace, p.port)
+		clusters[endpoints.Name] = presto.ClusterInfo{Host: host}
+	}
+
+	return clusters, nil
+}
+
+// hasReadyAddress reports whether endpoints has at least one subset with a
+// ready backing pod, i.e. whether its Service currently has anything to
+// route traffic to.
+func hasReadyAddress(endpoints *corev1.Endpoints) bool {
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
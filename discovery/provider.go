@@ -0,0 +1,10 @@
+// Package discovery provides pluggable cluster discovery for the exporter:
+// a static file provider, Kubernetes, Consul and AWS EMR, plus a
+// CompositeProvider that merges several of them into one.
+package discovery
+
+import "github.com/The-Data-Appeal-Company/presto-exporter/presto"
+
+// Provider discovers the set of clusters to scrape, keyed by cluster name.
+// It is the same contract as presto.ClusterProvider.
+type Provider = presto.ClusterProvider
@@ -0,0 +1,64 @@
+package discovery
+
+import "fmt"
+
+// Config bundles the settings needed by each discovery provider that
+// Select knows how to build.
+type Config struct {
+	File struct {
+		Path string
+	}
+	Kubernetes struct {
+		Namespace     string
+		LabelSelector string
+		Port          int
+	}
+	Consul struct {
+		Address     string
+		ServiceName string
+		Tag         string
+	}
+}
+
+// Select builds a Provider from the discovery source names requested via
+// the --discovery flag, e.g. []string{"file", "k8s", "emr"}. A single
+// source is returned as-is; multiple sources are merged with a
+// CompositeProvider, each wrapped with WithPrefix(provider, name) first so
+// that two sources returning the same cluster name don't collide and have
+// one silently dropped.
+func Select(names []string, cfg Config) (Provider, error) {
+	providers := make([]Provider, 0, len(names))
+
+	for _, name := range names {
+		provider, err := build(name, cfg)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+
+	if len(providers) == 1 {
+		return providers[0], nil
+	}
+
+	for i, name := range names {
+		providers[i] = WithPrefix(providers[i], name)
+	}
+
+	return NewCompositeProvider(providers...), nil
+}
+
+func build(name string, cfg Config) (Provider, error) {
+	switch name {
+	case "file":
+		return NewFileProvider(cfg.File.Path)
+	case "k8s":
+		return NewKubernetesProvider(cfg.Kubernetes.Namespace, cfg.Kubernetes.LabelSelector, cfg.Kubernetes.Port)
+	case "consul":
+		return NewConsulProvider(cfg.Consul.Address, cfg.Consul.ServiceName, cfg.Consul.Tag)
+	case "emr":
+		return NewEMRProvider(), nil
+	default:
+		return nil, fmt.Errorf("unknown discovery provider %q", name)
+	}
+}
@@ -0,0 +1,224 @@
+package presto
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/singleflight"
+)
+
+var (
+	scrapeDurationSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName("presto_exporter", "", "scrape_duration_seconds"),
+		"Time taken to scrape a single cluster.",
+		[]string{"cluster"}, nil,
+	)
+	scrapeErrorsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName("presto_exporter", "", "scrape_errors_total"),
+		"Total number of scrape errors, by cluster and error kind.",
+		[]string{"cluster", "kind"}, nil,
+	)
+	clustersMonitored = prometheus.NewDesc(
+		prometheus.BuildFQName("presto_exporter", "", "clusters_monitored"),
+		"Number of clusters currently returned by the cluster provider.",
+		nil, nil,
+	)
+	loginCacheHitsTotal = prometheus.NewDesc(
+		prometheus.BuildFQName("presto_exporter", "", "login_cache_hits_total"),
+		"Total number of times a cached Trino UI login cookie was reused instead of logging in again.",
+		[]string{"cluster"}, nil,
+	)
+)
+
+const defaultScrapeConcurrency = 4
+
+// CachingCollector wraps a Collector with a per-cluster TTL cache and
+// singleflight de-duplication, so that concurrent Prometheus scrapes (or a
+// slow coordinator) don't cause the same cluster to be scraped twice, and
+// scrapes a configurable number of clusters in parallel. It also reports
+// the exporter's own health alongside the wrapped Collector's metrics.
+type CachingCollector struct {
+	collector       *Collector
+	clusterProvider ClusterProvider
+	ttl             time.Duration
+	concurrency     int
+
+	group singleflight.Group
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+
+	errorMu sync.Mutex
+	errors  map[string]map[string]uint64
+}
+
+type cacheEntry struct {
+	metrics   []prometheus.Metric
+	expiresAt time.Time
+}
+
+// NewCachingCollector wraps collector, caching each cluster's scrape
+// result for ttl and scraping at most concurrency clusters at once.
+// concurrency <= 0 falls back to a sane default.
+func NewCachingCollector(collector *Collector, clusterProvider ClusterProvider, ttl time.Duration, concurrency int) *CachingCollector {
+	if concurrency <= 0 {
+		concurrency = defaultScrapeConcurrency
+	}
+
+	return &CachingCollector{
+		collector:       collector,
+		clusterProvider: clusterProvider,
+		ttl:             ttl,
+		concurrency:     concurrency,
+		cache:           make(map[string]cacheEntry),
+		errors:          make(map[string]map[string]uint64),
+	}
+}
+
+func (c *CachingCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.collector.Describe(ch)
+	ch <- scrapeDurationSeconds
+	ch <- scrapeErrorsTotal
+	ch <- clustersMonitored
+	ch <- loginCacheHitsTotal
+}
+
+func (c *CachingCollector) Collect(out chan<- prometheus.Metric) {
+	clusters, err := c.clusterProvider.Provide()
+	if err != nil {
+		logrus.Errorf("%s", err)
+		return
+	}
+
+	out <- prometheus.MustNewConstMetric(clustersMonitored, prometheus.GaugeValue, float64(len(clusters)))
+
+	names := make(chan string, len(clusters))
+	for name := range clusters {
+		names <- name
+	}
+	close(names)
+
+	results := make(chan []prometheus.Metric, len(clusters))
+
+	workers := c.concurrency
+	if workers > len(clusters) {
+		workers = len(clusters)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range names {
+				results <- c.scrapeClusterCached(name, clusters[name])
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	for metrics := range results {
+		for _, metric := range metrics {
+			out <- metric
+		}
+	}
+}
+
+// scrapeClusterCached returns the cached scrape result for name if it's
+// still within ttl, otherwise scrapes it (de-duplicated via singleflight so
+// concurrent scrapes of the same cluster only hit the network once) and
+// reports the exporter's own scrape_duration/scrape_errors metrics
+// alongside the cluster's own metrics.
+func (c *CachingCollector) scrapeClusterCached(name string, cluster ClusterInfo) []prometheus.Metric {
+	start := time.Now()
+
+	result, _, _ := c.group.Do(name, func() (interface{}, error) {
+		if metrics, ok := c.fromCache(name); ok {
+			return scrapeOutcome{metrics: metrics}, nil
+		}
+
+		metrics, errs := c.collector.scrapeCluster(name, cluster)
+		c.storeCache(name, metrics)
+
+		return scrapeOutcome{metrics: metrics, errs: errs}, nil
+	})
+
+	duration := time.Since(start).Seconds()
+
+	outcome, _ := result.(scrapeOutcome)
+	metrics := append([]prometheus.Metric{}, outcome.metrics...)
+	metrics = append(metrics,
+		prometheus.MustNewConstMetric(scrapeDurationSeconds, prometheus.GaugeValue, duration, name),
+		prometheus.MustNewConstMetric(loginCacheHitsTotal, prometheus.CounterValue, float64(c.collector.loginCacheHitCount(name)), name),
+	)
+
+	for _, scrapeErr := range outcome.errs {
+		c.recordScrapeError(name, scrapeErr.kind)
+	}
+
+	for kind, count := range c.scrapeErrorCounts(name) {
+		metrics = append(metrics, prometheus.MustNewConstMetric(scrapeErrorsTotal, prometheus.CounterValue, float64(count), name, kind))
+	}
+
+	return metrics
+}
+
+// recordScrapeError increments the running scrape-error count for
+// (cluster, kind), so presto_exporter_scrape_errors_total accumulates
+// across scrapes instead of flatlining at 1 for the duration of an outage.
+func (c *CachingCollector) recordScrapeError(cluster, kind string) {
+	c.errorMu.Lock()
+	defer c.errorMu.Unlock()
+
+	byKind, ok := c.errors[cluster]
+	if !ok {
+		byKind = make(map[string]uint64)
+		c.errors[cluster] = byKind
+	}
+	byKind[kind]++
+}
+
+// scrapeErrorCounts returns a snapshot of the running scrape-error counts
+// for cluster, exposed as presto_exporter_scrape_errors_total.
+func (c *CachingCollector) scrapeErrorCounts(cluster string) map[string]uint64 {
+	c.errorMu.Lock()
+	defer c.errorMu.Unlock()
+
+	counts := make(map[string]uint64, len(c.errors[cluster]))
+	for kind, count := range c.errors[cluster] {
+		counts[kind] = count
+	}
+
+	return counts
+}
+
+// scrapeOutcome is the value threaded through singleflight.Group.Do.
+type scrapeOutcome struct {
+	metrics []prometheus.Metric
+	errs    []scrapeError
+}
+
+func (c *CachingCollector) fromCache(name string) ([]prometheus.Metric, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.cache[name]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+
+	return entry.metrics, true
+}
+
+func (c *CachingCollector) storeCache(name string, metrics []prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cache[name] = cacheEntry{metrics: metrics, expiresAt: time.Now().Add(c.ttl)}
+}
@@ -0,0 +1,88 @@
+package presto
+
+import "time"
+
+// Distribution identifies which Presto/Trino flavour a cluster is running,
+// since the two expose cluster-wide statistics on different endpoints.
+type Distribution string
+
+const (
+	DistSql Distribution = "presto-sql"
+	DistDb  Distribution = "presto-db"
+)
+
+// ClusterProvider discovers the set of clusters to scrape, keyed by cluster name.
+type ClusterProvider interface {
+	Provide() (map[string]ClusterInfo, error)
+}
+
+// ClusterInfo describes how to reach and authenticate against a single cluster.
+type ClusterInfo struct {
+	Host         string
+	Distribution Distribution
+	Security     SecurityConfig
+	Auth         *AuthConfig
+}
+
+// SecurityConfig controls the HTTP transport used to talk to a cluster:
+// TLS/mTLS, static credentials and AWS SigV4 request signing.
+type SecurityConfig struct {
+	TLS         *TLSConfig
+	APIKey      string
+	BearerToken string
+	SigV4       *SigV4Config
+	Timeouts    Timeouts
+}
+
+// TLSConfig configures TLS/mTLS for a cluster's http.Client.
+type TLSConfig struct {
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	InsecureSkipVerify bool
+}
+
+// SigV4Config enables AWS IAM (SigV4) request signing, as used by Trino
+// coordinators fronted by an API Gateway or ALB with IAM auth enabled.
+// When RoleARN is set, the signer assumes that role before signing.
+type SigV4Config struct {
+	Region  string
+	RoleARN string
+}
+
+// Timeouts overrides the default dial/handshake/idle timeouts of a
+// cluster's http.Client. Zero values fall back to the package defaults.
+type Timeouts struct {
+	Dial           time.Duration
+	TLSHandshake   time.Duration
+	ExpectContinue time.Duration
+	IdleConn       time.Duration
+	Request        time.Duration
+}
+
+const (
+	defaultDialTimeout           = 10 * time.Second
+	defaultTLSHandshakeTimeout   = 10 * time.Second
+	defaultExpectContinueTimeout = 1 * time.Second
+	defaultIdleConnTimeout       = 90 * time.Second
+	defaultRequestTimeout        = 10 * time.Second
+)
+
+func (t Timeouts) withDefaults() Timeouts {
+	if t.Dial == 0 {
+		t.Dial = defaultDialTimeout
+	}
+	if t.TLSHandshake == 0 {
+		t.TLSHandshake = defaultTLSHandshakeTimeout
+	}
+	if t.ExpectContinue == 0 {
+		t.ExpectContinue = defaultExpectContinueTimeout
+	}
+	if t.IdleConn == 0 {
+		t.IdleConn = defaultIdleConnTimeout
+	}
+	if t.Request == 0 {
+		t.Request = defaultRequestTimeout
+	}
+	return t
+}
@@ -1,15 +1,14 @@
 package presto
 
 import (
-	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
+	lru "github.com/hashicorp/golang-lru"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 	"io/ioutil"
 	"net/http"
-	"time"
+	"sync"
 )
 
 var namespace = "presto_cluster"
@@ -67,24 +66,79 @@ var (
 	)
 )
 
+// Collector scrapes cluster-wide statistics from every cluster returned by
+// its ClusterProvider. Each cluster gets its own http.Client, built lazily
+// from its ClusterInfo.Security so that a mixed fleet (self-signed certs,
+// mTLS, IAM auth, ...) can be scraped from a single exporter.
 type Collector struct {
-	client          *http.Client
 	clusterProvider ClusterProvider
+
+	mu      sync.Mutex
+	clients map[string]*http.Client
+
+	queryMetricsEnabled bool
+	maxLabelValues      int
+
+	queryMu        sync.Mutex
+	knownUsers     *lru.Cache
+	knownSources   *lru.Cache
+	countedQueries *lru.Cache
+	completed      map[string]map[string]*completedCounter
+
+	sessionMu      sync.Mutex
+	sessions       map[string]SessionManager
+	loginCacheHits map[string]uint64
 }
 
-func NewCollector(clusterProvider ClusterProvider) Collector {
-	return Collector{
+func NewCollector(clusterProvider ClusterProvider, opts ...Option) *Collector {
+	c := &Collector{
 		clusterProvider: clusterProvider,
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-			CheckRedirect: func(*http.Request, []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
+		clients:         make(map[string]*http.Client),
+		maxLabelValues:  defaultMaxLabelValues,
+		sessions:        make(map[string]SessionManager),
+		loginCacheHits:  make(map[string]uint64),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.queryMetricsEnabled {
+		c.knownUsers, _ = lru.New(c.maxLabelValues)
+		c.knownSources, _ = lru.New(c.maxLabelValues)
+		c.countedQueries, _ = lru.New(countedQueriesCacheSize)
+		c.completed = make(map[string]map[string]*completedCounter)
+	}
+
+	return c
+}
+
+func (c *Collector) clientFor(name string, cluster ClusterInfo) (*http.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if client, ok := c.clients[name]; ok {
+		return client, nil
+	}
+
+	transport, err := newTransport(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("building transport for cluster %s: %w", name, err)
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   cluster.Security.Timeouts.withDefaults().Request,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
 		},
 	}
+
+	c.clients[name] = client
+	return client, nil
 }
 
-func (c Collector) Describe(ch chan<- *prometheus.Desc) {
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- runningQueries
 	ch <- blockedQueries
 	ch <- queuedQueries
@@ -95,9 +149,14 @@ func (c Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- totalInputBytes
 	ch <- totalCpuTimeSecs
 	ch <- up
+	ch <- queriesByState
+	ch <- queryElapsedSeconds
+	ch <- queryCpuSeconds
+	ch <- queryQueuedSeconds
+	ch <- queriesCompletedTotal
 }
 
-func (c Collector) Collect(out chan<- prometheus.Metric) {
+func (c *Collector) Collect(out chan<- prometheus.Metric) {
 	clusters, err := c.clusterProvider.Provide()
 	if err != nil {
 		logrus.Errorf("%s", err)
@@ -105,54 +164,95 @@ func (c Collector) Collect(out chan<- prometheus.Metric) {
 	}
 
 	for name, cluster := range clusters {
+		metrics, _ := c.scrapeCluster(name, cluster)
+		for _, metric := range metrics {
+			out <- metric
+		}
+	}
+}
+
+// scrapeError tags a scrape failure with a coarse kind (cluster stats vs.
+// query metrics), letting callers like CachingCollector report it as
+// presto_exporter_scrape_errors_total{cluster,kind}.
+type scrapeError struct {
+	kind string
+	err  error
+}
+
+// scrapeCluster performs a full scrape of a single cluster -- the
+// cluster-wide gauges and, if enabled, the query-level workload metrics --
+// and returns the resulting metrics rather than streaming them, so callers
+// can cache, retry or parallelize per cluster.
+func (c *Collector) scrapeCluster(name string, cluster ClusterInfo) ([]prometheus.Metric, []scrapeError) {
+	labelValues := []string{name}
+
+	response, err := c.statisticsFromCluster(name, cluster)
+	if err != nil {
+		logrus.Error(err)
+		return []prometheus.Metric{
+			prometheus.MustNewConstMetric(up, prometheus.GaugeValue, 0, labelValues...),
+		}, []scrapeError{{kind: "cluster", err: err}}
+	}
+
+	metrics := []prometheus.Metric{
+		prometheus.MustNewConstMetric(runningQueries, prometheus.GaugeValue, response.RunningQueries, labelValues...),
+		prometheus.MustNewConstMetric(blockedQueries, prometheus.GaugeValue, response.BlockedQueries, labelValues...),
+		prometheus.MustNewConstMetric(queuedQueries, prometheus.GaugeValue, response.QueuedQueries, labelValues...),
+		prometheus.MustNewConstMetric(activeWorkers, prometheus.GaugeValue, response.ActiveWorkers, labelValues...),
+		prometheus.MustNewConstMetric(runningDrivers, prometheus.GaugeValue, response.RunningDrivers, labelValues...),
+		prometheus.MustNewConstMetric(reservedMemory, prometheus.GaugeValue, response.ReservedMemory, labelValues...),
+		prometheus.MustNewConstMetric(totalInputRows, prometheus.GaugeValue, response.TotalInputRows, labelValues...),
+		prometheus.MustNewConstMetric(totalInputBytes, prometheus.GaugeValue, response.TotalInputBytes, labelValues...),
+		prometheus.MustNewConstMetric(totalCpuTimeSecs, prometheus.GaugeValue, response.TotalCpuTimeSecs, labelValues...),
+		prometheus.MustNewConstMetric(up, prometheus.GaugeValue, 1, labelValues...),
+	}
 
-		response, err := c.statisticsFromCluster(cluster)
-		labelValues := []string{name}
+	var errs []scrapeError
 
+	if c.queryMetricsEnabled {
+		client, err := c.clientFor(name, cluster)
 		if err != nil {
-			logrus.Error(err)
-			out <- prometheus.MustNewConstMetric(up, prometheus.GaugeValue, 0, labelValues...)
-			continue
+			errs = append(errs, scrapeError{kind: "query", err: err})
+		} else {
+			queryMetrics, err := c.scrapeQueryMetrics(name, cluster, client)
+			if err != nil {
+				errs = append(errs, scrapeError{kind: "query", err: err})
+			}
+			metrics = append(metrics, queryMetrics...)
 		}
-
-		out <- prometheus.MustNewConstMetric(runningQueries, prometheus.GaugeValue, response.RunningQueries, labelValues...)
-		out <- prometheus.MustNewConstMetric(blockedQueries, prometheus.GaugeValue, response.BlockedQueries, labelValues...)
-		out <- prometheus.MustNewConstMetric(queuedQueries, prometheus.GaugeValue, response.QueuedQueries, labelValues...)
-		out <- prometheus.MustNewConstMetric(activeWorkers, prometheus.GaugeValue, response.ActiveWorkers, labelValues...)
-		out <- prometheus.MustNewConstMetric(runningDrivers, prometheus.GaugeValue, response.RunningDrivers, labelValues...)
-		out <- prometheus.MustNewConstMetric(reservedMemory, prometheus.GaugeValue, response.ReservedMemory, labelValues...)
-		out <- prometheus.MustNewConstMetric(totalInputRows, prometheus.GaugeValue, response.TotalInputRows, labelValues...)
-		out <- prometheus.MustNewConstMetric(totalInputBytes, prometheus.GaugeValue, response.TotalInputBytes, labelValues...)
-		out <- prometheus.MustNewConstMetric(totalCpuTimeSecs, prometheus.GaugeValue, response.TotalCpuTimeSecs, labelValues...)
-		out <- prometheus.MustNewConstMetric(up, prometheus.GaugeValue, 1, labelValues...)
 	}
+
+	return metrics, errs
 }
 
-func (c Collector) statisticsFromCluster(cluster ClusterInfo) (Response, error) {
+func (c *Collector) statisticsFromCluster(name string, cluster ClusterInfo) (Response, error) {
+	client, err := c.clientFor(name, cluster)
+	if err != nil {
+		return Response{}, err
+	}
+
 	switch cluster.Distribution {
 	case DistSql:
-		return c.statsFromPrestoSQL(cluster)
+		return c.statsFromPrestoSQL(client, name, cluster)
 	case DistDb:
-		return c.statsFromPrestoDB(cluster)
+		return c.statsFromPrestoDB(client, cluster)
 	default:
 		return Response{}, fmt.Errorf("unsupported distribution %s", cluster.Distribution)
 	}
 }
 
-func (c Collector) statsFromPrestoDB(cluster ClusterInfo) (Response, error) {
+func (c *Collector) statsFromPrestoDB(client *http.Client, cluster ClusterInfo) (Response, error) {
 	url := fmt.Sprintf("%s/v1/cluster", cluster.Host)
-	resp, err := c.client.Get(url)
-
+	resp, err := client.Get(url)
 	if err != nil {
 		return Response{}, err
 	}
+	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return Response{}, err
+	if resp.StatusCode != http.StatusOK {
+		return Response{}, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
 	}
 
-	defer resp.Body.Close()
-
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		logrus.Error(err)
@@ -164,59 +264,113 @@ func (c Collector) statsFromPrestoDB(cluster ClusterInfo) (Response, error) {
 	return response, err
 }
 
-func (c Collector) statsFromPrestoSQL(cluster ClusterInfo) (Response, error) {
-	login, err := c.login(cluster)
+func (c *Collector) statsFromPrestoSQL(client *http.Client, name string, cluster ClusterInfo) (Response, error) {
+	session, err := c.sessionFor(name, cluster)
 	if err != nil {
 		return Response{}, err
 	}
 
-	apiStatsUrl := fmt.Sprintf("%s%s", cluster.Host, "/ui/api/stats")
-	req, err := http.NewRequest("GET", apiStatsUrl, nil)
+	response, status, err := c.fetchPrestoSQLStats(client, name, cluster, session)
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		session.Invalidate()
+		response, _, err = c.fetchPrestoSQLStats(client, name, cluster, session)
+	}
 	if err != nil {
 		return Response{}, err
 	}
 
-	req.Header.Set("Cookie", login)
+	return response, nil
+}
+
+// fetchPrestoSQLStats fetches /ui/api/stats and returns the response status
+// alongside the decoded body (or a zero Response for non-2xx statuses, which
+// is decided before unmarshalling so a 401/403's HTML/empty body never
+// masquerades as a JSON decode error) so callers can tell an expired session
+// apart from any other failure and retry after invalidating it.
+func (c *Collector) fetchPrestoSQLStats(client *http.Client, name string, cluster ClusterInfo, session SessionManager) (Response, int, error) {
+	headers, refreshed, err := session.Headers(client, cluster)
+	if err != nil {
+		return Response{}, 0, err
+	}
+	c.recordLoginRefresh(name, refreshed)
 
-	resp, err := c.client.Do(req)
+	apiStatsUrl := fmt.Sprintf("%s%s", cluster.Host, "/ui/api/stats")
+	req, err := http.NewRequest("GET", apiStatsUrl, nil)
 	if err != nil {
-		return Response{}, err
+		return Response{}, 0, err
 	}
+	addHeaders(req, headers)
 
+	resp, err := client.Do(req)
+	if err != nil {
+		return Response{}, 0, err
+	}
 
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return Response{}, resp.StatusCode, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, apiStatsUrl)
+	}
+
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return Response{}, err
+		return Response{}, resp.StatusCode, err
 	}
 
 	var response Response
-	err = json.Unmarshal(body, &response)
-	if err != nil {
-		return Response{}, err
+	if err := json.Unmarshal(body, &response); err != nil {
+		return Response{}, resp.StatusCode, err
 	}
 
-	return response, nil
+	return response, resp.StatusCode, nil
+}
+
+// addHeaders copies every value of every header in src onto req.
+func addHeaders(req *http.Request, src http.Header) {
+	for key, values := range src {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
 }
 
-func (c Collector) login(cluster ClusterInfo) (string, error) {
-	loginUrl := fmt.Sprintf("%s%s", cluster.Host, "/ui/login")
-	const contentType = "application/x-www-form-urlencoded"
-	const userName = "exporter"
-	body := bytes.NewBuffer([]byte(fmt.Sprintf("username=%s&password=&redirectPath=", userName)))
-	resp, err := c.client.Post(loginUrl, contentType, body)
+// sessionFor returns the SessionManager for a cluster, built from its
+// ClusterInfo.Auth the first time it's needed and reused afterwards so the
+// session it caches (cookie, OAuth2 token, ...) survives across scrapes.
+func (c *Collector) sessionFor(name string, cluster ClusterInfo) (SessionManager, error) {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+
+	if session, ok := c.sessions[name]; ok {
+		return session, nil
+	}
+
+	session, err := newSessionManager(cluster.Auth)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("building session manager for cluster %s: %w", name, err)
 	}
 
-	cookie := resp.Header.Get("Set-Cookie")
+	c.sessions[name] = session
+	return session, nil
+}
 
-	if cookie == "" {
-		return "", errors.New("no Set-Cookie header present in response")
+func (c *Collector) recordLoginRefresh(name string, refreshed bool) {
+	if refreshed {
+		return
 	}
 
-	return cookie, nil
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	c.loginCacheHits[name]++
+}
+
+// loginCacheHitCount reports how many times name's cached session was
+// reused without re-authenticating, exposed by CachingCollector as
+// presto_exporter_login_cache_hits_total.
+func (c *Collector) loginCacheHitCount(name string) uint64 {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	return c.loginCacheHits[name]
 }
 
 type Response struct {
@@ -0,0 +1,29 @@
+package presto
+
+// defaultMaxLabelValues caps the number of distinct users/sources tracked
+// by the query-level collector when no explicit limit is given.
+const defaultMaxLabelValues = 200
+
+// countedQueriesCacheSize bounds how many completed query IDs are
+// remembered to avoid double-counting presto_cluster_queries_completed_total
+// across scrapes (a query typically stays in /v1/query's response for a
+// while after finishing).
+const countedQueriesCacheSize = 10000
+
+// Option configures optional Collector behaviour.
+type Option func(*Collector)
+
+// WithQueryMetrics enables the expensive /v1/query scrape that exposes
+// per-state, per-user and per-source workload metrics, alongside the
+// cluster-wide gauges scraped from /v1/cluster. maxLabelValues bounds the
+// number of distinct user/source label values retained; anything beyond
+// that is reported under the "other" bucket to protect Prometheus from
+// unbounded cardinality.
+func WithQueryMetrics(maxLabelValues int) Option {
+	return func(c *Collector) {
+		c.queryMetricsEnabled = true
+		if maxLabelValues > 0 {
+			c.maxLabelValues = maxLabelValues
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package presto
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryInfo is the subset of a /v1/query entry used for workload metrics.
+type QueryInfo struct {
+	QueryId    string       `json:"queryId"`
+	State      string       `json:"state"`
+	Session    QuerySession `json:"session"`
+	QueryStats QueryStats   `json:"queryStats"`
+}
+
+type QuerySession struct {
+	User   string `json:"user"`
+	Source string `json:"source"`
+}
+
+type QueryStats struct {
+	ResourceGroupId []string `json:"resourceGroupId"`
+	ElapsedTime     string   `json:"elapsedTime"`
+	QueuedTime      string   `json:"queuedTime"`
+	TotalCpuTime    string   `json:"totalCpuTime"`
+}
+
+// terminalStates are the query states counted towards
+// presto_cluster_queries_completed_total.
+var terminalStates = map[string]bool{
+	"FINISHED": true,
+	"FAILED":   true,
+	"CANCELED": true,
+}
+
+func (c *Collector) fetchQueries(client *http.Client, name string, cluster ClusterInfo) ([]QueryInfo, error) {
+	if cluster.Distribution != DistSql {
+		queries, _, err := c.doFetchQueries(client, name, cluster, nil)
+		return queries, err
+	}
+
+	session, err := c.sessionFor(name, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	queries, status, err := c.doFetchQueries(client, name, cluster, session)
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		session.Invalidate()
+		queries, _, err = c.doFetchQueries(client, name, cluster, session)
+	}
+
+	return queries, err
+}
+
+// doFetchQueries fetches /v1/query and returns the response status
+// alongside the decoded queries (or nil for non-2xx statuses) so
+// fetchQueries can tell an expired session apart from any other failure and
+// retry after invalidating it, the same way statsFromPrestoSQL does.
+func (c *Collector) doFetchQueries(client *http.Client, name string, cluster ClusterInfo, session SessionManager) ([]QueryInfo, int, error) {
+	url := fmt.Sprintf("%s/v1/query", cluster.Host)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if session != nil {
+		headers, refreshed, err := session.Headers(client, cluster)
+		if err != nil {
+			return nil, 0, err
+		}
+		c.recordLoginRefresh(name, refreshed)
+		addHeaders(req, headers)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+
+	var queries []QueryInfo
+	if err := json.Unmarshal(body, &queries); err != nil {
+		return nil, resp.StatusCode, err
+	}
+
+	return queries, resp.StatusCode, nil
+}
+
+// resourceGroupLabel flattens a query's resource group path, e.g.
+// ["global", "etl"], into the dotted label value "global.etl".
+func resourceGroupLabel(segments []string) string {
+	return strings.Join(segments, ".")
+}
+
+// durationUnits maps io.airlift.units.Duration suffixes to their
+// time.Duration multiplier. Order matters: ms must be checked before s.
+var durationUnits = []struct {
+	suffix string
+	unit   time.Duration
+}{
+	{"ns", time.Nanosecond},
+	{"us", time.Microsecond},
+	{"ms", time.Millisecond},
+	{"s", time.Second},
+	{"m", time.Minute},
+	{"h", time.Hour},
+	{"d", 24 * time.Hour},
+}
+
+// parsePrestoDuration parses durations as rendered by Presto/Trino's
+// io.airlift.units.Duration, e.g. "123.45ms", "2.00m", "1.50h".
+func parsePrestoDuration(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, fmt.Errorf("empty duration")
+	}
+
+	for _, u := range durationUnits {
+		if !strings.HasSuffix(raw, u.suffix) {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(strings.TrimSuffix(raw, u.suffix), 64)
+		if err != nil {
+			return 0, fmt.Errorf("parsing duration %q: %w", raw, err)
+		}
+
+		return time.Duration(value * float64(u.unit)), nil
+	}
+
+	return 0, fmt.Errorf("unrecognized duration format %q", raw)
+}
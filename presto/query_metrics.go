@@ -0,0 +1,187 @@
+package presto
+
+import (
+	"fmt"
+	"net/http"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	queriesByState = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "queries_by_state"),
+		"Number of queries currently in a given state.",
+		[]string{"cluster_name", "state"}, nil,
+	)
+	queryElapsedSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "query_elapsed_seconds"),
+		"Elapsed time of queries currently returned by /v1/query.",
+		[]string{"cluster_name"}, nil,
+	)
+	queryCpuSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "query_cpu_seconds"),
+		"CPU time of queries currently returned by /v1/query.",
+		[]string{"cluster_name"}, nil,
+	)
+	queryQueuedSeconds = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "query_queued_seconds"),
+		"Queued time of queries currently returned by /v1/query.",
+		[]string{"cluster_name"}, nil,
+	)
+	queriesCompletedTotal = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "queries_completed_total"),
+		"Total number of completed queries observed, by final state, user, source and resource group.",
+		[]string{"cluster_name", "state", "user", "source", "resource_group"}, nil,
+	)
+)
+
+// queryDurationBuckets covers 100ms to ~3.4 minutes, enough range for
+// typical interactive and batch Presto/Trino queries.
+var queryDurationBuckets = prometheus.ExponentialBuckets(0.1, 2, 12)
+
+// completedCounter is the running total behind one
+// presto_cluster_queries_completed_total time series.
+type completedCounter struct {
+	state, user, source, resourceGroup string
+	value                              float64
+}
+
+// scrapeQueryMetrics scrapes /v1/query for cluster and returns per-state,
+// per-user and per-source workload metrics. Metrics accumulated so far are
+// still returned alongside a fetch error, so a single bad scrape doesn't
+// throw away the rest of the cluster's metrics.
+func (c *Collector) scrapeQueryMetrics(name string, cluster ClusterInfo, client *http.Client) ([]prometheus.Metric, error) {
+	queries, err := c.fetchQueries(client, name, cluster)
+	if err != nil {
+		return nil, fmt.Errorf("fetching query metrics for cluster %s: %w", name, err)
+	}
+
+	stateCounts := make(map[string]float64)
+	var elapsed, cpu, queued []float64
+
+	for _, query := range queries {
+		stateCounts[query.State]++
+
+		if d, err := parsePrestoDuration(query.QueryStats.ElapsedTime); err == nil {
+			elapsed = append(elapsed, d.Seconds())
+		}
+		if d, err := parsePrestoDuration(query.QueryStats.TotalCpuTime); err == nil {
+			cpu = append(cpu, d.Seconds())
+		}
+		if d, err := parsePrestoDuration(query.QueryStats.QueuedTime); err == nil {
+			queued = append(queued, d.Seconds())
+		}
+
+		if terminalStates[query.State] {
+			c.recordCompletedQuery(name, query)
+		}
+	}
+
+	var metrics []prometheus.Metric
+
+	for state, count := range stateCounts {
+		metrics = append(metrics, prometheus.MustNewConstMetric(queriesByState, prometheus.GaugeValue, count, name, state))
+	}
+
+	metrics = append(metrics,
+		buildHistogram(queryElapsedSeconds, elapsed, name),
+		buildHistogram(queryCpuSeconds, cpu, name),
+		buildHistogram(queryQueuedSeconds, queued, name),
+	)
+
+	for _, counter := range c.completedCounters(name) {
+		metrics = append(metrics, prometheus.MustNewConstMetric(
+			queriesCompletedTotal, prometheus.CounterValue, counter.value,
+			name, counter.state, counter.user, counter.source, counter.resourceGroup,
+		))
+	}
+
+	return metrics, nil
+}
+
+// recordCompletedQuery increments the completed-queries counter for query,
+// de-duplicating by query ID so a query finished in a previous scrape isn't
+// counted again while it's still present in /v1/query's response.
+func (c *Collector) recordCompletedQuery(cluster string, query QueryInfo) {
+	c.queryMu.Lock()
+	defer c.queryMu.Unlock()
+
+	dedupKey := fmt.Sprintf("%s/%s", cluster, query.QueryId)
+	if c.countedQueries.Contains(dedupKey) {
+		return
+	}
+	c.countedQueries.Add(dedupKey, struct{}{})
+
+	user := c.capLabelValue(c.knownUsers, query.Session.User)
+	source := c.capLabelValue(c.knownSources, query.Session.Source)
+	resourceGroup := resourceGroupLabel(query.QueryStats.ResourceGroupId)
+
+	byCluster, ok := c.completed[cluster]
+	if !ok {
+		byCluster = make(map[string]*completedCounter)
+		c.completed[cluster] = byCluster
+	}
+
+	key := fmt.Sprintf("%s/%s/%s/%s", query.State, user, source, resourceGroup)
+	counter, ok := byCluster[key]
+	if !ok {
+		counter = &completedCounter{state: query.State, user: user, source: source, resourceGroup: resourceGroup}
+		byCluster[key] = counter
+	}
+
+	counter.value++
+}
+
+func (c *Collector) completedCounters(cluster string) []*completedCounter {
+	c.queryMu.Lock()
+	defer c.queryMu.Unlock()
+
+	byCluster := c.completed[cluster]
+	counters := make([]*completedCounter, 0, len(byCluster))
+	for _, counter := range byCluster {
+		snapshot := *counter
+		counters = append(counters, &snapshot)
+	}
+
+	return counters
+}
+
+// capLabelValue bounds the distinct values used for a label: the first
+// maxLabelValues distinct inputs seen pass through unchanged, anything
+// after that collapses into "other" so a runaway number of distinct
+// users/sources can't blow up this collector's cardinality.
+func (c *Collector) capLabelValue(known *lru.Cache, value string) string {
+	if value == "" {
+		return value
+	}
+
+	if known.Contains(value) {
+		return value
+	}
+
+	if known.Len() < c.maxLabelValues {
+		known.Add(value, struct{}{})
+		return value
+	}
+
+	return "other"
+}
+
+// buildHistogram turns a slice of raw observations into a Prometheus
+// const histogram with the package's default duration buckets.
+func buildHistogram(desc *prometheus.Desc, values []float64, labelValues ...string) prometheus.Metric {
+	buckets := make(map[float64]uint64, len(queryDurationBuckets))
+	var sum float64
+
+	for _, value := range values {
+		sum += value
+		for _, bucket := range queryDurationBuckets {
+			if value <= bucket {
+				buckets[bucket]++
+			}
+		}
+	}
+
+	return prometheus.MustNewConstHistogram(desc, uint64(len(values)), sum, buckets, labelValues...)
+}
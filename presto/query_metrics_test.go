@@ -0,0 +1,54 @@
+package presto
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestCapLabelValue(t *testing.T) {
+	c := NewCollector(nil, WithQueryMetrics(2))
+
+	if got := c.capLabelValue(c.knownUsers, ""); got != "" {
+		t.Fatalf("capLabelValue(%q) = %q, want empty passthrough", "", got)
+	}
+
+	if got := c.capLabelValue(c.knownUsers, "alice"); got != "alice" {
+		t.Fatalf("capLabelValue(alice) = %q, want unchanged", got)
+	}
+	if got := c.capLabelValue(c.knownUsers, "bob"); got != "bob" {
+		t.Fatalf("capLabelValue(bob) = %q, want unchanged", got)
+	}
+
+	// maxLabelValues is 2 and both slots are taken, so a third distinct
+	// value collapses into "other".
+	if got := c.capLabelValue(c.knownUsers, "carol"); got != "other" {
+		t.Fatalf("capLabelValue(carol) = %q, want \"other\" once the cap is reached", got)
+	}
+
+	// A value already tracked still passes through unchanged.
+	if got := c.capLabelValue(c.knownUsers, "alice"); got != "alice" {
+		t.Fatalf("capLabelValue(alice) = %q, want unchanged on repeat", got)
+	}
+}
+
+func TestBuildHistogram(t *testing.T) {
+	metric := buildHistogram(queryElapsedSeconds, []float64{0.05, 0.2, 5}, "cluster-a")
+
+	var dtoMetric dto.Metric
+	if err := metric.Write(&dtoMetric); err != nil {
+		t.Fatalf("writing metric: %v", err)
+	}
+
+	histogram := dtoMetric.GetHistogram()
+	if got, want := histogram.GetSampleCount(), uint64(3); got != want {
+		t.Fatalf("sample count = %d, want %d", got, want)
+	}
+	if got, want := histogram.GetSampleSum(), 0.05+0.2+5; got != want {
+		t.Fatalf("sample sum = %v, want %v", got, want)
+	}
+
+	if len(dtoMetric.GetLabel()) == 0 {
+		t.Fatal("expected cluster_name label to be set")
+	}
+}
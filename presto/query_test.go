@@ -0,0 +1,46 @@
+package presto
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePrestoDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "nanoseconds", raw: "123ns", want: 123 * time.Nanosecond},
+		{name: "microseconds", raw: "2us", want: 2 * time.Microsecond},
+		{name: "milliseconds before seconds", raw: "123.45ms", want: time.Duration(123.45 * float64(time.Millisecond))},
+		{name: "seconds", raw: "1.5s", want: 1500 * time.Millisecond},
+		{name: "minutes", raw: "2.00m", want: 2 * time.Minute},
+		{name: "hours", raw: "1.50h", want: 90 * time.Minute},
+		{name: "days", raw: "1d", want: 24 * time.Hour},
+		{name: "whitespace is trimmed", raw: "  10s  ", want: 10 * time.Second},
+		{name: "empty input", raw: "", wantErr: true},
+		{name: "garbage input", raw: "banana", wantErr: true},
+		{name: "missing unit", raw: "42", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePrestoDuration(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parsePrestoDuration(%q) = %v, want error", tt.raw, got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("parsePrestoDuration(%q) returned unexpected error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Fatalf("parsePrestoDuration(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package presto
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// csrfHeaderName is the header Trino's UI echoes a CSRF token through on
+// login and expects back on subsequent requests, when CSRF protection is
+// enabled.
+const csrfHeaderName = "X-Trino-CSRF-Token"
+
+// AuthMode selects which SessionManager implementation a cluster
+// authenticates with.
+type AuthMode string
+
+const (
+	AuthForm   AuthMode = "form"
+	AuthOAuth2 AuthMode = "oauth2"
+	AuthStatic AuthMode = "static"
+)
+
+// AuthConfig selects and configures a cluster's SessionManager. A nil
+// *AuthConfig (the zero value of ClusterInfo.Auth) falls back to form
+// login with the default "exporter" username.
+type AuthConfig struct {
+	Mode   AuthMode
+	Form   FormAuthConfig
+	OAuth2 OAuth2AuthConfig
+	Static StaticAuthConfig
+}
+
+type FormAuthConfig struct {
+	Username string
+}
+
+type OAuth2AuthConfig struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+type StaticAuthConfig struct {
+	JWT       string
+	TrinoUser string
+}
+
+// SessionManager authenticates against a cluster's UI/API and returns the
+// headers that must be attached to each request. Implementations cache
+// their session and only re-authenticate when it's missing, expired or
+// explicitly invalidated (e.g. after a 401/403 from the API).
+type SessionManager interface {
+	// Headers returns the headers to attach to a request. refreshed
+	// reports whether authenticating this call required a network
+	// round-trip, as opposed to reusing a cached session.
+	Headers(client *http.Client, cluster ClusterInfo) (headers http.Header, refreshed bool, err error)
+
+	// Invalidate discards any cached session, forcing the next call to
+	// Headers to re-authenticate.
+	Invalidate()
+}
+
+// newSessionManager builds the SessionManager selected by auth, defaulting
+// to form login when auth is nil.
+func newSessionManager(auth *AuthConfig) (SessionManager, error) {
+	if auth == nil {
+		return NewFormSessionManager(FormAuthConfig{}), nil
+	}
+
+	switch auth.Mode {
+	case "", AuthForm:
+		return NewFormSessionManager(auth.Form), nil
+	case AuthOAuth2:
+		return NewOAuth2SessionManager(auth.OAuth2), nil
+	case AuthStatic:
+		return NewStaticSessionManager(auth.Static), nil
+	default:
+		return nil, fmt.Errorf("unsupported auth mode %q", auth.Mode)
+	}
+}
+
+// FormSessionManager authenticates with Trino's username/password UI
+// login form and reuses the resulting session cookie (and CSRF token, if
+// the cluster sends one) until it's invalidated.
+type FormSessionManager struct {
+	username string
+
+	mu     sync.Mutex
+	cookie string
+	csrf   string
+}
+
+func NewFormSessionManager(cfg FormAuthConfig) *FormSessionManager {
+	username := cfg.Username
+	if username == "" {
+		username = "exporter"
+	}
+	return &FormSessionManager{username: username}
+}
+
+func (s *FormSessionManager) Headers(client *http.Client, cluster ClusterInfo) (http.Header, bool, error) {
+	s.mu.Lock()
+	cookie, csrf := s.cookie, s.csrf
+	s.mu.Unlock()
+
+	refreshed := false
+
+	if cookie == "" {
+		var err error
+		cookie, csrf, err = s.login(client, cluster)
+		if err != nil {
+			return nil, false, err
+		}
+
+		s.mu.Lock()
+		s.cookie, s.csrf = cookie, csrf
+		s.mu.Unlock()
+
+		refreshed = true
+	}
+
+	headers := make(http.Header)
+	headers.Set("Cookie", cookie)
+	if csrf != "" {
+		headers.Set(csrfHeaderName, csrf)
+	}
+
+	return headers, refreshed, nil
+}
+
+func (s *FormSessionManager) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cookie, s.csrf = "", ""
+}
+
+func (s *FormSessionManager) login(client *http.Client, cluster ClusterInfo) (cookie, csrf string, err error) {
+	loginUrl := fmt.Sprintf("%s/ui/login", cluster.Host)
+	const contentType = "application/x-www-form-urlencoded"
+	body := bytes.NewBufferString(fmt.Sprintf("username=%s&password=&redirectPath=", s.username))
+
+	resp, err := client.Post(loginUrl, contentType, body)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
+	cookie = resp.Header.Get("Set-Cookie")
+	if cookie == "" {
+		return "", "", errors.New("no Set-Cookie header present in response")
+	}
+
+	return cookie, resp.Header.Get(csrfHeaderName), nil
+}
+
+// OAuth2SessionManager fetches and refreshes a bearer token via the OAuth2
+// client-credentials flow, for clusters configured with an OAuth2
+// authenticator.
+type OAuth2SessionManager struct {
+	cfg OAuth2AuthConfig
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func NewOAuth2SessionManager(cfg OAuth2AuthConfig) *OAuth2SessionManager {
+	return &OAuth2SessionManager{cfg: cfg}
+}
+
+func (s *OAuth2SessionManager) Headers(client *http.Client, cluster ClusterInfo) (http.Header, bool, error) {
+	s.mu.Lock()
+	token, expiresAt := s.token, s.expiresAt
+	s.mu.Unlock()
+
+	refreshed := false
+
+	if token == "" || time.Now().After(expiresAt) {
+		var err error
+		token, expiresAt, err = s.fetchToken(client)
+		if err != nil {
+			return nil, false, err
+		}
+
+		s.mu.Lock()
+		s.token, s.expiresAt = token, expiresAt
+		s.mu.Unlock()
+
+		refreshed = true
+	}
+
+	headers := make(http.Header)
+	headers.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	return headers, refreshed, nil
+}
+
+func (s *OAuth2SessionManager) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = ""
+}
+
+func (s *OAuth2SessionManager) fetchToken(client *http.Client) (string, time.Time, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", s.cfg.ClientID)
+	form.Set("client_secret", s.cfg.ClientSecret)
+	if len(s.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.cfg.Scopes, " "))
+	}
+
+	resp, err := client.PostForm(s.cfg.TokenURL, form)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("oauth2 token request to %s failed with status %d", s.cfg.TokenURL, resp.StatusCode)
+	}
+
+	var payload struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", time.Time{}, fmt.Errorf("decoding oauth2 token response: %w", err)
+	}
+
+	return payload.AccessToken, time.Now().Add(time.Duration(payload.ExpiresIn) * time.Second), nil
+}
+
+// StaticSessionManager attaches a fixed JWT and/or X-Trino-User header to
+// every request, for clusters that authenticate at the network edge (e.g.
+// a service mesh or gateway injecting identity) rather than via a login
+// flow.
+type StaticSessionManager struct {
+	cfg StaticAuthConfig
+}
+
+func NewStaticSessionManager(cfg StaticAuthConfig) *StaticSessionManager {
+	return &StaticSessionManager{cfg: cfg}
+}
+
+func (s *StaticSessionManager) Headers(client *http.Client, cluster ClusterInfo) (http.Header, bool, error) {
+	headers := make(http.Header)
+	if s.cfg.JWT != "" {
+		headers.Set("Authorization", fmt.Sprintf("Bearer %s", s.cfg.JWT))
+	}
+	if s.cfg.TrinoUser != "" {
+		headers.Set("X-Trino-User", s.cfg.TrinoUser)
+	}
+
+	return headers, false, nil
+}
+
+func (s *StaticSessionManager) Invalidate() {}
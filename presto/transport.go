@@ -0,0 +1,105 @@
+package presto
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+)
+
+// newTransport builds the http.RoundTripper used to talk to a single
+// cluster, wiring up TLS/mTLS, static credentials and SigV4 signing
+// according to cluster.Security.
+func newTransport(cluster ClusterInfo) (http.RoundTripper, error) {
+	timeouts := cluster.Security.Timeouts.withDefaults()
+
+	tlsConfig, err := buildTLSConfig(cluster.Security.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("building TLS config: %w", err)
+	}
+
+	var roundTripper http.RoundTripper = &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: timeouts.Dial,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   timeouts.TLSHandshake,
+		ExpectContinueTimeout: timeouts.ExpectContinue,
+		IdleConnTimeout:       timeouts.IdleConn,
+	}
+
+	if cluster.Security.SigV4 != nil {
+		roundTripper, err = newSigV4RoundTripper(roundTripper, *cluster.Security.SigV4)
+		if err != nil {
+			return nil, fmt.Errorf("building AWS SigV4 transport: %w", err)
+		}
+	}
+
+	if cluster.Security.APIKey != "" {
+		roundTripper = &apiKeyRoundTripper{next: roundTripper, apiKey: cluster.Security.APIKey}
+	}
+
+	if cluster.Security.BearerToken != "" {
+		roundTripper = &bearerTokenRoundTripper{next: roundTripper, token: cluster.Security.BearerToken}
+	}
+
+	return roundTripper, nil
+}
+
+func buildTLSConfig(cfg *TLSConfig) (*tls.Config, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+
+	if cfg.CAFile != "" {
+		caCert, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file %s: %w", cfg.CAFile, err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no valid certificates found in %s", cfg.CAFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" && cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// apiKeyRoundTripper injects a static API key header on every request.
+type apiKeyRoundTripper struct {
+	next   http.RoundTripper
+	apiKey string
+}
+
+func (r *apiKeyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("X-API-Key", r.apiKey)
+	return r.next.RoundTrip(req)
+}
+
+// bearerTokenRoundTripper injects a static bearer token on every request.
+type bearerTokenRoundTripper struct {
+	next  http.RoundTripper
+	token string
+}
+
+func (r *bearerTokenRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", r.token))
+	return r.next.RoundTrip(req)
+}